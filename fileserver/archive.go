@@ -0,0 +1,418 @@
+package fileserver
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+)
+
+// @Summary Compress files or directories into an archive
+// @Description Create a zip or tar.gz archive from one or more paths
+// @Tags archive
+// @Accept json
+// @Produce json
+// @Success 201 {object} UploadResponse "Archive created"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 413 {object} ErrorResponse "Archive too large"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /files/{path} [post]
+func (fs *FileServer) compressOp(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Format  string   `json:"format"`
+		Sources []string `json:"sources"`
+		Dest    string   `json:"dest"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fs.writeError(w, r, "Invalid request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.Format != "zip" && req.Format != "tar.gz" {
+		fs.writeError(w, r, "Unsupported archive format", http.StatusBadRequest,
+			fmt.Sprintf("format '%s' must be 'zip' or 'tar.gz'", req.Format))
+		return
+	}
+
+	destKey, err := fs.securePath(req.Dest)
+	if err != nil {
+		fs.writeError(w, r, "Invalid destination path", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := fs.authorizePath(r, destKey, true); err != nil {
+		fs.writeError(w, r, "Access denied", http.StatusForbidden, err.Error())
+		return
+	}
+
+	sourceKeys := make([]string, 0, len(req.Sources))
+	for _, src := range req.Sources {
+		key, err := fs.securePath(src)
+		if err != nil {
+			fs.writeError(w, r, "Invalid source path", http.StatusBadRequest, fmt.Sprintf("'%s': %s", src, err.Error()))
+			return
+		}
+		if err := fs.authorizePath(r, key, false); err != nil {
+			fs.writeError(w, r, "Access denied", http.StatusForbidden, err.Error())
+			return
+		}
+		sourceKeys = append(sourceKeys, key)
+	}
+
+	pr, pw := io.Pipe()
+	// budget allows one byte past maxFileSize through so a legitimate
+	// archive whose content is exactly maxFileSize bytes doesn't get
+	// rejected: budgetedReader errors on the first Read after its budget
+	// reaches zero, which would otherwise come before the source's EOF.
+	// If Put fails with errArchiveTooLarge, the partial archive is deleted.
+	budget := fs.maxFileSize + 1
+
+	go func() {
+		var err error
+		switch req.Format {
+		case "zip":
+			err = fs.writeZipArchive(pw, sourceKeys, budget)
+		case "tar.gz":
+			err = fs.writeTarGzArchive(pw, sourceKeys, budget)
+		}
+		pw.CloseWithError(err)
+	}()
+
+	written, err := fs.backend.Put(destKey, pr)
+	if err != nil {
+		if err == errArchiveTooLarge {
+			fs.backend.Delete(destKey, false)
+			fs.writeError(w, r, "Archive too large", http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("uncompressed content exceeds maximum %d", fs.maxFileSize))
+			return
+		}
+		fs.writeError(w, r, "Cannot create archive", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	fs.logRequest(r, http.StatusCreated, fmt.Sprintf("compressed %d source(s) into %s (%d bytes)", len(sourceKeys), req.Dest, written))
+	fs.writeOpSuccess(w, r, "Archive created successfully", req.Dest, http.StatusCreated)
+}
+
+var errArchiveTooLarge = fmt.Errorf("archive content exceeds maximum allowed size")
+
+// budgetedReader caps the cumulative bytes read across many calls, used to
+// enforce maxFileSize over an entire archive rather than a single file.
+type budgetedReader struct {
+	r         io.Reader
+	remaining *int64
+}
+
+func (b *budgetedReader) Read(p []byte) (int, error) {
+	if *b.remaining <= 0 {
+		return 0, errArchiveTooLarge
+	}
+	if int64(len(p)) > *b.remaining {
+		p = p[:*b.remaining]
+	}
+	n, err := b.r.Read(p)
+	*b.remaining -= int64(n)
+	return n, err
+}
+
+func (fs *FileServer) writeZipArchive(w io.Writer, sources []string, budget int64) error {
+	zw := zip.NewWriter(w)
+
+	for _, src := range sources {
+		if err := fs.addToZip(zw, src, &budget); err != nil {
+			zw.Close()
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func (fs *FileServer) addToZip(zw *zip.Writer, key string, budget *int64) error {
+	info, err := fs.backend.Stat(key)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		entries, err := fs.backend.List(key)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.Name() == aclFileName {
+				continue
+			}
+			if err := fs.addToZip(zw, path.Join(key, entry.Name()), budget); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = key
+	header.Method = zip.Deflate
+
+	dst, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	src, err := fs.backend.Open(key)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	_, err = io.Copy(dst, &budgetedReader{r: src, remaining: budget})
+	return err
+}
+
+func (fs *FileServer) writeTarGzArchive(w io.Writer, sources []string, budget int64) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	for _, src := range sources {
+		if err := fs.addToTar(tw, src, &budget); err != nil {
+			tw.Close()
+			gw.Close()
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+func (fs *FileServer) addToTar(tw *tar.Writer, key string, budget *int64) error {
+	info, err := fs.backend.Stat(key)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		entries, err := fs.backend.List(key)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.Name() == aclFileName {
+				continue
+			}
+			if err := fs.addToTar(tw, path.Join(key, entry.Name()), budget); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = key
+
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	src, err := fs.backend.Open(key)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	_, err = io.Copy(tw, &budgetedReader{r: src, remaining: budget})
+	return err
+}
+
+// @Summary Decompress an archive
+// @Description Extract a zip or tar.gz archive into a destination directory
+// @Tags archive
+// @Accept json
+// @Produce json
+// @Success 201 {object} UploadResponse "Archive extracted"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 413 {object} ErrorResponse "Expanded content too large"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /files/{path} [post]
+func (fs *FileServer) decompressOp(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Src  string `json:"src"`
+		Dest string `json:"dest"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fs.writeError(w, r, "Invalid request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	srcKey, err := fs.securePath(req.Src)
+	if err != nil {
+		fs.writeError(w, r, "Invalid source path", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := fs.authorizePath(r, srcKey, false); err != nil {
+		fs.writeError(w, r, "Access denied", http.StatusForbidden, err.Error())
+		return
+	}
+
+	destKey, err := fs.securePath(req.Dest)
+	if err != nil {
+		fs.writeError(w, r, "Invalid destination path", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := fs.authorizePath(r, destKey, true); err != nil {
+		fs.writeError(w, r, "Access denied", http.StatusForbidden, err.Error())
+		return
+	}
+
+	archive, err := fs.backend.Open(srcKey)
+	if err != nil {
+		fs.writeError(w, r, "Cannot read archive", http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer archive.Close()
+
+	// See compressOp: +1 lets budgetedReader distinguish "exactly at the
+	// limit" from "over the limit" instead of erroring one read early.
+	budget := fs.maxFileSize + 1
+	extracted := 0
+
+	switch {
+	case hasSuffix(req.Src, ".zip"):
+		extracted, err = fs.extractZip(archive, destKey, &budget)
+	case hasSuffix(req.Src, ".tar.gz") || hasSuffix(req.Src, ".tgz"):
+		extracted, err = fs.extractTarGz(archive, destKey, &budget)
+	default:
+		fs.writeError(w, r, "Unsupported archive format", http.StatusBadRequest,
+			"source file must end in .zip, .tar.gz or .tgz")
+		return
+	}
+
+	if err != nil {
+		if err == errArchiveTooLarge {
+			fs.writeError(w, r, "Expanded content too large", http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("decompressed content exceeds maximum %d", fs.maxFileSize))
+			return
+		}
+		fs.writeError(w, r, "Cannot extract archive", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	fs.logRequest(r, http.StatusCreated, fmt.Sprintf("decompressed %s into %s (%d entries)", req.Src, req.Dest, extracted))
+	fs.writeOpSuccess(w, r, "Archive extracted successfully", req.Dest, http.StatusCreated)
+}
+
+func hasSuffix(s, suffix string) bool {
+	return len(s) >= len(suffix) && s[len(s)-len(suffix):] == suffix
+}
+
+// extractZip reads the whole archive first because archive/zip requires
+// an io.ReaderAt; a temp buffer is fine since maxFileSize already bounds it.
+func (fs *FileServer) extractZip(r io.Reader, destKey string, budget *int64) (int, error) {
+	data, err := io.ReadAll(io.LimitReader(r, fs.maxFileSize+1))
+	if err != nil {
+		return 0, err
+	}
+
+	zr, err := zip.NewReader(byteReaderAt(data), int64(len(data)))
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, entry := range zr.File {
+		entryKey, err := fs.securePath(path.Join(destKey, entry.Name))
+		if err != nil {
+			return count, fmt.Errorf("unsafe archive entry %q: %w", entry.Name, err)
+		}
+
+		if entry.FileInfo().IsDir() {
+			if err := fs.backend.Mkdir(entryKey); err != nil {
+				return count, err
+			}
+			continue
+		}
+
+		src, err := entry.Open()
+		if err != nil {
+			return count, err
+		}
+
+		_, err = fs.backend.Put(entryKey, &budgetedReader{r: src, remaining: budget})
+		src.Close()
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+func (fs *FileServer) extractTarGz(r io.Reader, destKey string, budget *int64) (int, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, err
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	count := 0
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+
+		entryKey, err := fs.securePath(path.Join(destKey, header.Name))
+		if err != nil {
+			return count, fmt.Errorf("unsafe archive entry %q: %w", header.Name, err)
+		}
+
+		if header.Typeflag == tar.TypeDir {
+			if err := fs.backend.Mkdir(entryKey); err != nil {
+				return count, err
+			}
+			continue
+		}
+
+		if _, err := fs.backend.Put(entryKey, &budgetedReader{r: tr, remaining: budget}); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// byteReaderAt adapts a byte slice to io.ReaderAt for zip.NewReader.
+type byteReaderAt []byte
+
+func (b byteReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= int64(len(b)) {
+		return 0, io.EOF
+	}
+	n := copy(p, b[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
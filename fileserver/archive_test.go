@@ -0,0 +1,152 @@
+package fileserver
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newArchiveTestServer(t *testing.T, maxFileSize int64) (*FileServer, StorageBackend) {
+	t.Helper()
+	backend := NewMemoryBackend()
+	fs := New(WithStorageBackend(backend), WithMaxFileSize(maxFileSize))
+	return fs, backend
+}
+
+func doDecompress(t *testing.T, fs *FileServer, src, dest string) *httptest.ResponseRecorder {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"src": src, "dest": dest})
+	req := httptest.NewRequest(http.MethodPost, "/?op=decompress", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	fs.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestDecompressRejectsZipSlip makes sure an archive entry that tries to
+// escape the extraction directory via "../" is rejected instead of being
+// written outside dest.
+func TestDecompressRejectsZipSlip(t *testing.T) {
+	fs, backend := newArchiveTestServer(t, 1<<20)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("../../escaped.txt")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := w.Write([]byte("pwned")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if _, err := backend.Put("archive.zip", &buf); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	rec := doDecompress(t, fs, "archive.zip", "extracted")
+	if rec.Code == http.StatusCreated {
+		t.Fatalf("expected zip-slip entry to be rejected, got status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if backend.Exists("escaped.txt") {
+		t.Fatal("zip-slip entry escaped the extraction directory")
+	}
+}
+
+func doCompress(t *testing.T, fs *FileServer, format string, sources []string, dest string) *httptest.ResponseRecorder {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{"format": format, "sources": sources, "dest": dest})
+	req := httptest.NewRequest(http.MethodPost, "/?op=compress", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	fs.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestCompressExcludesAclFile makes sure compressing a directory that
+// contains a .ghs.yml doesn't leak its allow/deny rules into the archive,
+// even though the directory itself is readable.
+func TestCompressExcludesAclFile(t *testing.T) {
+	fs, backend := newArchiveTestServer(t, 1<<20)
+
+	if _, err := backend.Put("docs/.ghs.yml", bytes.NewReader([]byte("deny: [bob]\n"))); err != nil {
+		t.Fatalf("Put docs/.ghs.yml: %v", err)
+	}
+	if _, err := backend.Put("docs/readme.txt", bytes.NewReader([]byte("hello"))); err != nil {
+		t.Fatalf("Put docs/readme.txt: %v", err)
+	}
+
+	rec := doCompress(t, fs, "zip", []string{"docs"}, "archive.zip")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("compress: got status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	archiveFile, err := backend.Open("archive.zip")
+	if err != nil {
+		t.Fatalf("Open archive.zip: %v", err)
+	}
+	data, err := io.ReadAll(archiveFile)
+	archiveFile.Close()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("zip.NewReader: %v", err)
+	}
+	for _, entry := range zr.File {
+		if strings.HasSuffix(entry.Name, aclFileName) {
+			t.Fatalf("archive leaked ACL file: %s", entry.Name)
+		}
+	}
+}
+
+// TestCompressAllowsExactlyMaxFileSize makes sure content totaling exactly
+// maxFileSize isn't rejected by the zip-bomb budget's off-by-one.
+func TestCompressAllowsExactlyMaxFileSize(t *testing.T) {
+	fs, backend := newArchiveTestServer(t, 1024)
+
+	if _, err := backend.Put("exact.txt", bytes.NewReader([]byte(strings.Repeat("a", 1024)))); err != nil {
+		t.Fatalf("Put exact.txt: %v", err)
+	}
+
+	rec := doCompress(t, fs, "zip", []string{"exact.txt"}, "archive.zip")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+}
+
+// TestDecompressEnforcesSizeBudget makes sure a highly compressible
+// archive (a zip bomb) can't expand past maxFileSize.
+func TestDecompressEnforcesSizeBudget(t *testing.T) {
+	fs, backend := newArchiveTestServer(t, 1024)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("huge.txt")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := w.Write([]byte(strings.Repeat("a", 10*1024))); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if _, err := backend.Put("bomb.zip", &buf); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	rec := doDecompress(t, fs, "bomb.zip", "extracted")
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("got status %d, want %d: %s", rec.Code, http.StatusRequestEntityTooLarge, rec.Body.String())
+	}
+}
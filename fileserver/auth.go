@@ -0,0 +1,168 @@
+package fileserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Authenticator verifies the credentials on a request. Implementations
+// are responsible for both checking the request (Authenticate) and for
+// telling the client how to supply credentials when it didn't
+// (Challenge, e.g. setting WWW-Authenticate).
+type Authenticator interface {
+	// Authenticate returns the authenticated user and true if r carries
+	// valid credentials, or "" and false otherwise.
+	Authenticate(r *http.Request) (user string, ok bool)
+
+	// Challenge sets any response headers needed to prompt the client
+	// for credentials. It is called before the 401 response is written.
+	Challenge(w http.ResponseWriter)
+}
+
+// aclFileName is the per-directory ACL file, checked from the requested
+// path up to the data root, nearest ancestor wins.
+const aclFileName = ".ghs.yml"
+
+// aclRule is the shape of an aclFileName file.
+type aclRule struct {
+	Allow    []string `yaml:"allow"`
+	Deny     []string `yaml:"deny"`
+	ReadOnly *bool    `yaml:"readOnly"`
+}
+
+// userContextKey is the context key the authenticated user is stored
+// under, so handlers that need to check ACLs on paths beyond
+// r.URL.Path (e.g. a rename's destination) can reuse the same identity
+// authorize already established.
+type userContextKey struct{}
+
+// userFromContext returns the user authorize established for r, if any.
+func userFromContext(r *http.Request) (string, bool) {
+	user, ok := r.Context().Value(userContextKey{}).(string)
+	return user, ok
+}
+
+// authorize runs authentication and ACL authorization for r. It writes
+// the appropriate error response and returns the (possibly unchanged)
+// request and false if the request should not proceed to the normal
+// handlers. On success it returns r with the authenticated user attached
+// to its context.
+func (fs *FileServer) authorize(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	user, ok := fs.auth.Authenticate(r)
+	if !ok {
+		fs.auth.Challenge(w)
+		fs.writeError(w, r, "Authentication required", http.StatusUnauthorized, "valid credentials are required")
+		return r, false
+	}
+
+	r = r.WithContext(context.WithValue(r.Context(), userContextKey{}, user))
+
+	key, err := fs.securePath(r.URL.Path)
+	if err != nil {
+		// Let the normal handler reject the malformed path.
+		return r, true
+	}
+
+	if err := fs.authorizePath(r, key, r.Method != http.MethodGet); err != nil {
+		fs.writeError(w, r, "Access denied", http.StatusForbidden, err.Error())
+		return r, false
+	}
+
+	return r, true
+}
+
+// authorizePath checks that the user authorize attached to r's context is
+// permitted to access key under any per-path ACL, including the
+// ReadOnly flag when write is true. Handlers that resolve additional
+// paths beyond r.URL.Path (a rename's destination, an archive's sources)
+// must call this for each of them, since authorize only ever checks
+// r.URL.Path itself. It is a no-op when no Authenticator is configured.
+func (fs *FileServer) authorizePath(r *http.Request, key string, write bool) error {
+	if fs.auth == nil {
+		return nil
+	}
+
+	user, _ := userFromContext(r)
+	rule := fs.resolveACL(key)
+
+	if !rule.allows(user) {
+		return fmt.Errorf("user '%s' is not permitted to access '%s'", user, key)
+	}
+
+	if write && rule.ReadOnly != nil && *rule.ReadOnly {
+		return fmt.Errorf("'%s' is read-only for this user", key)
+	}
+
+	return nil
+}
+
+func (rule *aclRule) allows(user string) bool {
+	for _, denied := range rule.Deny {
+		if denied == user {
+			return false
+		}
+	}
+	if len(rule.Allow) == 0 {
+		return true
+	}
+	for _, allowed := range rule.Allow {
+		if allowed == user {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveACL walks from the directory containing key up to the data
+// root, merging aclFileName files. The nearest ancestor's Allow, Deny,
+// and ReadOnly values take precedence; any left unset fall back to the
+// next ancestor up.
+func (fs *FileServer) resolveACL(key string) *aclRule {
+	dir := key
+	if info, err := fs.backend.Stat(key); err == nil && !info.IsDir() {
+		dir = path.Dir(key)
+	}
+
+	merged := &aclRule{}
+
+	for {
+		if rule := fs.readACLFile(dir); rule != nil {
+			if merged.Allow == nil {
+				merged.Allow = rule.Allow
+			}
+			if merged.Deny == nil {
+				merged.Deny = rule.Deny
+			}
+			if merged.ReadOnly == nil {
+				merged.ReadOnly = rule.ReadOnly
+			}
+		}
+
+		if dir == "." {
+			break
+		}
+		dir = path.Dir(dir)
+	}
+
+	return merged
+}
+
+func (fs *FileServer) readACLFile(dir string) *aclRule {
+	data, err := fs.backend.Open(path.Join(dir, aclFileName))
+	if err != nil {
+		return nil
+	}
+	defer data.Close()
+
+	var rule aclRule
+	if err := yaml.NewDecoder(data).Decode(&rule); err != nil {
+		fs.logger.Printf("ignoring invalid %s in %s: %v", aclFileName, dir, err)
+		return nil
+	}
+
+	return &rule
+}
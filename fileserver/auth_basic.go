@@ -0,0 +1,51 @@
+package fileserver
+
+import (
+	"fmt"
+	"net/http"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// basicAuthenticator implements Authenticator using HTTP Basic
+// credentials checked against bcrypt password hashes.
+type basicAuthenticator struct {
+	realm string
+	users map[string][]byte // username -> bcrypt hash
+}
+
+// WithBasicAuthUsers authenticates requests with HTTP Basic auth,
+// checking the supplied password against users[username], a bcrypt
+// hash produced with e.g. `htpasswd -nbB`.
+func WithBasicAuthUsers(users map[string]string) Option {
+	hashes := make(map[string][]byte, len(users))
+	for user, hash := range users {
+		hashes[user] = []byte(hash)
+	}
+
+	return func(fs *FileServer) {
+		fs.auth = &basicAuthenticator{realm: "file-server", users: hashes}
+	}
+}
+
+func (b *basicAuthenticator) Authenticate(r *http.Request) (string, bool) {
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return "", false
+	}
+
+	hash, ok := b.users[user]
+	if !ok {
+		return "", false
+	}
+
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil {
+		return "", false
+	}
+
+	return user, true
+}
+
+func (b *basicAuthenticator) Challenge(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Basic realm=%q`, b.realm))
+}
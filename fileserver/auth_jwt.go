@@ -0,0 +1,54 @@
+package fileserver
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwtAuthenticator implements Authenticator using JWT bearer tokens.
+type jwtAuthenticator struct {
+	keyfunc           jwt.Keyfunc
+	allowedAlgorithms []string
+}
+
+// WithJWTVerifier authenticates requests with a JWT passed in the
+// Authorization: Bearer header, verified using keyfunc (see
+// jwt.Keyfunc). allowedAlgorithms restricts which signing algorithms
+// (e.g. "RS256", "HS256") a token's "alg" header is allowed to use; it
+// must be non-empty, since accepting whatever algorithm a token claims
+// opens the door to algorithm-confusion attacks (e.g. an attacker
+// forging an HS256 token signed with a known RSA public key). The
+// token's "sub" claim, if present, becomes the authenticated user for
+// ACL checks.
+func WithJWTVerifier(keyfunc jwt.Keyfunc, allowedAlgorithms []string) Option {
+	return func(fs *FileServer) {
+		fs.auth = &jwtAuthenticator{keyfunc: keyfunc, allowedAlgorithms: allowedAlgorithms}
+	}
+}
+
+func (j *jwtAuthenticator) Authenticate(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	raw, found := strings.CutPrefix(header, "Bearer ")
+	if !found || raw == "" {
+		return "", false
+	}
+
+	token, err := jwt.Parse(raw, j.keyfunc, jwt.WithValidMethods(j.allowedAlgorithms))
+	if err != nil || !token.Valid {
+		return "", false
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", true
+	}
+
+	sub, _ := claims["sub"].(string)
+	return sub, true
+}
+
+func (j *jwtAuthenticator) Challenge(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+}
@@ -0,0 +1,162 @@
+package fileserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// headerAuthenticator authenticates whatever user a test request names in
+// X-Test-User, so a single FileServer can be driven as different users.
+type headerAuthenticator struct{}
+
+func (headerAuthenticator) Authenticate(r *http.Request) (string, bool) {
+	user := r.Header.Get("X-Test-User")
+	if user == "" {
+		return "", false
+	}
+	return user, true
+}
+
+func (headerAuthenticator) Challenge(w http.ResponseWriter) {}
+
+func doAs(t *testing.T, fs *FileServer, method, target, user string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	req.Header.Set("X-Test-User", user)
+	rec := httptest.NewRecorder()
+	fs.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestACLDeniesUnlistedUser(t *testing.T) {
+	backend := NewMemoryBackend()
+	if _, err := backend.Put(".ghs.yml", bytes.NewReader([]byte("allow: [alice]\n"))); err != nil {
+		t.Fatalf("Put .ghs.yml: %v", err)
+	}
+
+	fs := New(WithStorageBackend(backend), WithAuth(headerAuthenticator{}))
+
+	if rec := doAs(t, fs, http.MethodGet, "/", "bob", nil); rec.Code != http.StatusForbidden {
+		t.Fatalf("bob: got status %d, want %d: %s", rec.Code, http.StatusForbidden, rec.Body.String())
+	}
+	if rec := doAs(t, fs, http.MethodGet, "/", "alice", nil); rec.Code != http.StatusOK {
+		t.Fatalf("alice: got status %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
+
+func TestGhsYmlNotServable(t *testing.T) {
+	backend := NewMemoryBackend()
+	if _, err := backend.Put(".ghs.yml", bytes.NewReader([]byte("allow: [alice]\n"))); err != nil {
+		t.Fatalf("Put .ghs.yml: %v", err)
+	}
+
+	fs := New(WithStorageBackend(backend))
+
+	rec := httptest.NewRequest(http.MethodGet, "/.ghs.yml", nil)
+	w := httptest.NewRecorder()
+	fs.ServeHTTP(w, rec)
+	if w.Code == http.StatusOK {
+		t.Fatalf("expected .ghs.yml to be unreachable via GET, got 200: %s", w.Body.String())
+	}
+
+	put := httptest.NewRequest(http.MethodPut, "/.ghs.yml", bytes.NewReader([]byte("allow: [mallory]\n")))
+	putRec := httptest.NewRecorder()
+	fs.ServeHTTP(putRec, put)
+	if putRec.Code == http.StatusOK || putRec.Code == http.StatusCreated {
+		t.Fatalf("expected PUT to .ghs.yml to be rejected, got %d", putRec.Code)
+	}
+}
+
+// TestACLEnforcedOnMoveDestination makes sure a user who is denied access
+// to a directory can't write into it by naming it as the "to" of a move,
+// even though their own source path is permitted.
+func TestACLEnforcedOnMoveDestination(t *testing.T) {
+	backend := NewMemoryBackend()
+	if _, err := backend.Put("secret/.ghs.yml", bytes.NewReader([]byte("deny: [bob]\n"))); err != nil {
+		t.Fatalf("Put secret/.ghs.yml: %v", err)
+	}
+	if _, err := backend.Put("public/a.txt", bytes.NewReader([]byte("hello"))); err != nil {
+		t.Fatalf("Put public/a.txt: %v", err)
+	}
+
+	fs := New(WithStorageBackend(backend), WithAuth(headerAuthenticator{}))
+
+	rec := doAs(t, fs, http.MethodPost, "/public/a.txt?op=move&to=/secret/a.txt", "bob", nil)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("got status %d, want %d: %s", rec.Code, http.StatusForbidden, rec.Body.String())
+	}
+
+	if !backend.Exists("public/a.txt") {
+		t.Fatal("source file should not have moved")
+	}
+	if backend.Exists("secret/a.txt") {
+		t.Fatal("move into a denied destination should not have happened")
+	}
+}
+
+func TestJWTRejectsUnexpectedAlgorithm(t *testing.T) {
+	secret := []byte("shared-secret")
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "alice"})
+	signed, err := token.SignedString(secret)
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+
+	keyfunc := func(t *jwt.Token) (any, error) { return secret, nil }
+
+	strict := WithJWTVerifier(keyfunc, []string{"RS256"})
+	fs := &FileServer{}
+	strict(fs)
+
+	strictReq := httptest.NewRequest(http.MethodGet, "/", nil)
+	strictReq.Header.Set("Authorization", "Bearer "+signed)
+	if _, ok := fs.auth.Authenticate(strictReq); ok {
+		t.Fatal("expected HS256 token to be rejected when only RS256 is allowed")
+	}
+
+	permissive := WithJWTVerifier(keyfunc, []string{"HS256"})
+	fs2 := &FileServer{}
+	permissive(fs2)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+signed)
+	user, ok := fs2.auth.Authenticate(req)
+	if !ok || user != "alice" {
+		t.Fatalf("got (%q, %v), want (\"alice\", true)", user, ok)
+	}
+}
+
+func TestBatchDeleteReportsPartialProgressOnFailure(t *testing.T) {
+	backend := NewMemoryBackend()
+	if _, err := backend.Put("a.txt", bytes.NewReader([]byte("a"))); err != nil {
+		t.Fatalf("Put a.txt: %v", err)
+	}
+
+	fs := New(WithStorageBackend(backend))
+
+	body, _ := json.Marshal([]string{"/a.txt", "/missing.txt"})
+	req := httptest.NewRequest(http.MethodPost, "/?op=batchDelete", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	fs.ServeHTTP(rec, req)
+
+	if rec.Code == http.StatusOK {
+		t.Fatalf("expected batchDelete to fail on a missing path, got 200: %s", rec.Body.String())
+	}
+
+	var resp BatchDeleteErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if len(resp.Deleted) != 1 || resp.Deleted[0] != "/a.txt" {
+		t.Fatalf("got Deleted %v, want [/a.txt]", resp.Deleted)
+	}
+	if backend.Exists("a.txt") {
+		t.Fatal("a.txt should have been deleted before the batch failed")
+	}
+}
@@ -11,8 +11,10 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -22,6 +24,16 @@ type FileServer struct {
 	allowDelete bool
 	maxFileSize int64
 	logger      *log.Logger
+	backend     StorageBackend
+
+	uploadsMu sync.Mutex
+	uploads   map[string]*uploadSession
+
+	indexInterval time.Duration
+	indexMu       sync.RWMutex
+	index         []indexEntry
+
+	auth Authenticator
 }
 
 type Option func(*FileServer)
@@ -36,10 +48,11 @@ type FileInfo struct {
 }
 
 type DirectoryResponse struct {
-	Path      string     `json:"path" example:"/folder"`
-	Files     []FileInfo `json:"files"`
-	TotalSize int64      `json:"totalSize" example:"10240"`
-	Count     int        `json:"count" example:"5"`
+	Path       string     `json:"path" example:"/folder"`
+	Files      []FileInfo `json:"files"`
+	TotalSize  int64      `json:"totalSize" example:"10240"`
+	Count      int        `json:"count" example:"5"`
+	TotalCount int        `json:"totalCount" example:"42"`
 }
 
 type ErrorResponse struct {
@@ -56,6 +69,22 @@ type UploadResponse struct {
 	Timestamp string `json:"timestamp" example:"2024-01-01T12:00:00Z"`
 }
 
+// BatchDeleteResponse reports the paths deleted by a successful
+// batchDelete operation.
+type BatchDeleteResponse struct {
+	Message   string   `json:"message" example:"Successfully deleted"`
+	Deleted   []string `json:"deleted"`
+	Timestamp string   `json:"timestamp" example:"2024-01-01T12:00:00Z"`
+}
+
+// BatchDeleteErrorResponse extends ErrorResponse with the paths that were
+// already deleted before a batchDelete operation failed partway through,
+// so a caller can tell which of its requested paths are gone.
+type BatchDeleteErrorResponse struct {
+	ErrorResponse
+	Deleted []string `json:"deleted"`
+}
+
 func WithDataDir(dataDir string) Option {
 	return func(fs *FileServer) {
 		cleanedPath := path.Clean(dataDir)
@@ -92,22 +121,60 @@ func WithLogger(logger *log.Logger) Option {
 	}
 }
 
+// WithStorageBackend backs the file server with sb instead of the local
+// filesystem, letting the same HTTP surface serve cloud object storage
+// (see NewS3Backend) or an in-memory store for tests.
+func WithStorageBackend(sb StorageBackend) Option {
+	return func(fs *FileServer) {
+		fs.backend = sb
+	}
+}
+
+// WithIndexInterval sets how often the background search index is
+// rebuilt. It defaults to 10 minutes.
+func WithIndexInterval(interval time.Duration) Option {
+	return func(fs *FileServer) {
+		fs.indexInterval = interval
+	}
+}
+
+// WithAuth requires every request to be authenticated by a, and
+// authorized against any per-path ACLs, before it reaches the normal
+// GET/PUT/DELETE/POST/PATCH handlers.
+func WithAuth(a Authenticator) Option {
+	return func(fs *FileServer) {
+		fs.auth = a
+	}
+}
+
 func New(options ...Option) *FileServer {
 	fs := &FileServer{
-		dataDir:     "./",
-		readOnly:    false,
-		allowDelete: true,
-		maxFileSize: 100 * 1024 * 1024, // 100MB default
-		logger:      log.Default(),
+		dataDir:       "./",
+		readOnly:      false,
+		allowDelete:   true,
+		maxFileSize:   100 * 1024 * 1024, // 100MB default
+		logger:        log.Default(),
+		uploads:       make(map[string]*uploadSession),
+		indexInterval: 10 * time.Minute,
 	}
 
 	for _, option := range options {
 		option(fs)
 	}
 
+	if fs.backend == nil {
+		fs.backend = newLocalFS(fs.dataDir)
+	}
+
+	go fs.runIndexer()
+	go fs.runUploadReaper()
+
 	return fs
 }
 
+// securePath validates urlPath and returns it as a key relative to the
+// data root, suitable for passing to fs.backend. It rejects any path
+// that would escape the data root via "..".
 func (fs *FileServer) securePath(urlPath string) (string, error) {
 	cleanedPath := filepath.Clean(urlPath)
 
@@ -116,14 +183,13 @@ func (fs *FileServer) securePath(urlPath string) (string, error) {
 	}
 
 	cleanedPath = strings.TrimPrefix(cleanedPath, "/")
-	fullPath := filepath.Join(fs.dataDir, cleanedPath)
 
 	absDataDir, err := filepath.Abs(fs.dataDir)
 	if err != nil {
 		return "", fmt.Errorf("cannot resolve data directory: %w", err)
 	}
 
-	absFullPath, err := filepath.Abs(fullPath)
+	absFullPath, err := filepath.Abs(filepath.Join(fs.dataDir, cleanedPath))
 	if err != nil {
 		return "", fmt.Errorf("cannot resolve requested path: %w", err)
 	}
@@ -132,7 +198,19 @@ func (fs *FileServer) securePath(urlPath string) (string, error) {
 		return "", errors.New("path outside of allowed directory")
 	}
 
-	return fullPath, nil
+	if cleanedPath == "" {
+		cleanedPath = "."
+	}
+
+	if cleanedPath == uploadsDir || strings.HasPrefix(cleanedPath, uploadsDir+"/") {
+		return "", errors.New("access to in-progress upload storage is not allowed")
+	}
+
+	if path.Base(cleanedPath) == aclFileName {
+		return "", errors.New("access to ACL files is not allowed")
+	}
+
+	return cleanedPath, nil
 }
 
 func (fs *FileServer) logRequest(r *http.Request, status int, message string) {
@@ -151,13 +229,18 @@ func (fs *FileServer) logRequest(r *http.Request, status int, message string) {
 // @Failure 404 {object} ErrorResponse "File not found"
 // @Router /{path} [get]
 func (fs *FileServer) getRequest(w http.ResponseWriter, r *http.Request) {
-	requestedFile, err := fs.securePath(r.URL.Path)
+	if r.URL.Query().Get("op") == "search" {
+		fs.searchOp(w, r)
+		return
+	}
+
+	key, err := fs.securePath(r.URL.Path)
 	if err != nil {
 		fs.writeError(w, r, "Invalid path", http.StatusBadRequest, err.Error())
 		return
 	}
 
-	stats, err := os.Stat(requestedFile)
+	stats, err := fs.backend.Stat(key)
 	if err != nil {
 		if os.IsNotExist(err) {
 			fs.writeError(w, r, "File not found", http.StatusNotFound, fmt.Sprintf("'%s' does not exist", r.URL.Path))
@@ -168,7 +251,7 @@ func (fs *FileServer) getRequest(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if stats.IsDir() {
-		fs.serveDirectory(w, r, requestedFile)
+		fs.serveDirectory(w, r, key)
 		return
 	}
 
@@ -176,7 +259,10 @@ func (fs *FileServer) getRequest(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Last-Modified", stats.ModTime().UTC().Format(http.TimeFormat))
 
 	fs.logRequest(r, http.StatusOK, fmt.Sprintf("served file: %s (%d bytes)", r.URL.Path, stats.Size()))
-	http.ServeFile(w, r, requestedFile)
+
+	if err := fs.backend.ServeFile(w, r, key); err != nil {
+		fs.writeError(w, r, "Cannot serve file", http.StatusInternalServerError, err.Error())
+	}
 }
 
 // @Summary Upload or create file
@@ -199,7 +285,7 @@ func (fs *FileServer) putRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	requestedFile, err := fs.securePath(r.URL.Path)
+	key, err := fs.securePath(r.URL.Path)
 	if err != nil {
 		fs.writeError(w, r, "Invalid path", http.StatusBadRequest, err.Error())
 		return
@@ -211,40 +297,60 @@ func (fs *FileServer) putRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dir := filepath.Dir(requestedFile)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		fs.writeError(w, r, "Cannot create directory", http.StatusInternalServerError, err.Error())
-		return
-	}
+	isNew := !fs.backend.Exists(key)
 
-	_, err = os.Stat(requestedFile)
-	isNew := os.IsNotExist(err)
+	var written int64
+	status := http.StatusOK
+	message := "File updated successfully"
 
-	file, err := os.Create(requestedFile)
-	if err != nil {
-		fs.writeError(w, r, "Cannot create file", http.StatusInternalServerError, err.Error())
-		return
-	}
-	defer file.Close()
+	if rangeHeader := r.Header.Get("Content-Range"); rangeHeader != "" {
+		start, end, total, err := parseContentRange(rangeHeader)
+		if err != nil {
+			fs.writeError(w, r, "Invalid Content-Range", http.StatusBadRequest, err.Error())
+			return
+		}
+		if end+1 > fs.maxFileSize || total > fs.maxFileSize {
+			fs.writeError(w, r, "File too large", http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("File size %d exceeds maximum %d", total, fs.maxFileSize))
+			return
+		}
 
-	written, err := io.CopyN(file, r.Body, fs.maxFileSize+1)
-	if err != nil && err != io.EOF {
-		fs.writeError(w, r, "Cannot write file content", http.StatusInternalServerError, err.Error())
-		return
-	}
+		if _, err := fs.backend.WriteAt(key, io.LimitReader(r.Body, end-start+1), start); err != nil {
+			fs.writeError(w, r, "Cannot write file content", http.StatusInternalServerError, err.Error())
+			return
+		}
+		written = end + 1
 
-	if written > fs.maxFileSize {
-		os.Remove(requestedFile)
-		fs.writeError(w, r, "File too large", http.StatusRequestEntityTooLarge,
-			fmt.Sprintf("File size exceeds maximum %d", fs.maxFileSize))
-		return
-	}
+		if end+1 < total {
+			fs.logRequest(r, http.StatusPartialContent, fmt.Sprintf("partial write: %s (%d-%d/%d)", r.URL.Path, start, end, total))
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, total))
+			w.WriteHeader(http.StatusPartialContent)
+			return
+		}
 
-	status := http.StatusOK
-	message := "File updated successfully"
-	if isNew {
-		status = http.StatusCreated
-		message = "File created successfully"
+		message = "File updated successfully"
+		if isNew {
+			status = http.StatusCreated
+			message = "File created successfully"
+		}
+	} else {
+		written, err = fs.backend.Put(key, io.LimitReader(r.Body, fs.maxFileSize+1))
+		if err != nil {
+			fs.writeError(w, r, "Cannot write file content", http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if written > fs.maxFileSize {
+			fs.backend.Delete(key, false)
+			fs.writeError(w, r, "File too large", http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("File size exceeds maximum %d", fs.maxFileSize))
+			return
+		}
+
+		if isNew {
+			status = http.StatusCreated
+			message = "File created successfully"
+		}
 	}
 
 	response := UploadResponse{
@@ -284,13 +390,13 @@ func (fs *FileServer) deleteRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	requestedFile, err := fs.securePath(r.URL.Path)
+	key, err := fs.securePath(r.URL.Path)
 	if err != nil {
 		fs.writeError(w, r, "Invalid path", http.StatusBadRequest, err.Error())
 		return
 	}
 
-	stats, err := os.Stat(requestedFile)
+	stats, err := fs.backend.Stat(key)
 	if err != nil {
 		if os.IsNotExist(err) {
 			fs.writeError(w, r, "File not found", http.StatusNotFound, fmt.Sprintf("'%s' does not exist", r.URL.Path))
@@ -300,18 +406,8 @@ func (fs *FileServer) deleteRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if stats.IsDir() {
-		recursive := r.URL.Query().Get("recursive") == "true"
-		if recursive {
-			err = os.RemoveAll(requestedFile)
-		} else {
-			err = os.Remove(requestedFile)
-		}
-	} else {
-		err = os.Remove(requestedFile)
-	}
-
-	if err != nil {
+	recursive := stats.IsDir() && r.URL.Query().Get("recursive") == "true"
+	if err := fs.backend.Delete(key, recursive); err != nil {
 		fs.writeError(w, r, "Cannot delete", http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -342,8 +438,8 @@ func (fs *FileServer) writeError(w http.ResponseWriter, r *http.Request, message
 	json.NewEncoder(w).Encode(errorResponse)
 }
 
-func (fs *FileServer) serveDirectory(w http.ResponseWriter, r *http.Request, dirPath string) {
-	entries, err := os.ReadDir(dirPath)
+func (fs *FileServer) serveDirectory(w http.ResponseWriter, r *http.Request, key string) {
+	entries, err := fs.backend.List(key)
 	if err != nil {
 		fs.writeError(w, r, "Cannot read directory", http.StatusInternalServerError, err.Error())
 		return
@@ -352,33 +448,31 @@ func (fs *FileServer) serveDirectory(w http.ResponseWriter, r *http.Request, dir
 	var files []FileInfo
 	var totalSize int64
 
-	relativePath := strings.TrimPrefix(dirPath, fs.dataDir)
-	if relativePath == "" {
-		relativePath = "/"
+	relativePath := "/"
+	if key != "." {
+		relativePath = "/" + key
 	}
 
-	for _, entry := range entries {
-
-		info, err := entry.Info()
-		if err != nil {
+	for _, info := range entries {
+		if key == "." && info.Name() == uploadsDir {
 			continue
 		}
-
-		fullPath := filepath.Join(relativePath, entry.Name())
-		if filepath.Separator != '/' {
-			fullPath = strings.ReplaceAll(fullPath, string(filepath.Separator), "/")
+		if info.Name() == aclFileName {
+			continue
 		}
 
+		fullPath := path.Join(relativePath, info.Name())
+
 		fileInfo := FileInfo{
-			Name:    entry.Name(),
-			IsDir:   entry.IsDir(),
+			Name:    info.Name(),
+			IsDir:   info.IsDir(),
 			Size:    info.Size(),
 			ModTime: info.ModTime().UTC().Format(time.RFC3339),
 			Path:    fullPath,
 		}
 
-		if !entry.IsDir() {
-			if mimeType := mime.TypeByExtension(filepath.Ext(entry.Name())); mimeType != "" {
+		if !info.IsDir() {
+			if mimeType := mime.TypeByExtension(filepath.Ext(info.Name())); mimeType != "" {
 				fileInfo.MimeType = mimeType
 			}
 			totalSize += info.Size()
@@ -387,25 +481,87 @@ func (fs *FileServer) serveDirectory(w http.ResponseWriter, r *http.Request, dir
 		files = append(files, fileInfo)
 	}
 
+	totalCount := len(files)
+	sortFiles(files, r.URL.Query().Get("sort"), r.URL.Query().Get("order"))
+	files = paginate(files, r.URL.Query().Get("offset"), r.URL.Query().Get("limit"))
+
 	response := DirectoryResponse{
-		Path:      relativePath,
-		Files:     files,
-		TotalSize: totalSize,
-		Count:     len(files),
+		Path:       relativePath,
+		Files:      files,
+		TotalSize:  totalSize,
+		Count:      len(files),
+		TotalCount: totalCount,
 	}
 
-	fs.logRequest(r, http.StatusOK, fmt.Sprintf("listed directory: %s (%d items)", relativePath, len(files)))
+	fs.logRequest(r, http.StatusOK, fmt.Sprintf("listed directory: %s (%d of %d items)", relativePath, len(files), totalCount))
+
+	if acceptsHTML(r) {
+		if err := renderDirectoryHTML(w, response); err != nil {
+			fs.writeError(w, r, "Cannot render directory", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+// sortFiles orders files in place by the given field ("name", "size", or
+// "mtime"; defaults to "name") and order ("asc" or "desc"; defaults to
+// "asc"). Directories and files are sorted together within the same list.
+func sortFiles(files []FileInfo, field, order string) {
+	less := func(i, j int) bool {
+		switch field {
+		case "size":
+			return files[i].Size < files[j].Size
+		case "mtime":
+			return files[i].ModTime < files[j].ModTime
+		default:
+			return files[i].Name < files[j].Name
+		}
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		if order == "desc" {
+			return less(j, i)
+		}
+		return less(i, j)
+	})
+}
+
+// paginate slices files according to the offset and limit query
+// parameters, ignoring either when absent or invalid.
+func paginate(files []FileInfo, rawOffset, rawLimit string) []FileInfo {
+	offset := 0
+	if parsed, err := strconv.Atoi(rawOffset); err == nil && parsed > 0 {
+		offset = parsed
+	}
+	if offset >= len(files) {
+		return []FileInfo{}
+	}
+	files = files[offset:]
+
+	if parsed, err := strconv.Atoi(rawLimit); err == nil && parsed >= 0 && parsed < len(files) {
+		files = files[:parsed]
+	}
+
+	return files
+}
+
 func (fs *FileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodOptions {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
+	if fs.auth != nil {
+		var ok bool
+		r, ok = fs.authorize(w, r)
+		if !ok {
+			return
+		}
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		fs.getRequest(w, r)
@@ -413,6 +569,10 @@ func (fs *FileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		fs.putRequest(w, r)
 	case http.MethodDelete:
 		fs.deleteRequest(w, r)
+	case http.MethodPost:
+		fs.postRequest(w, r)
+	case http.MethodPatch:
+		fs.patchRequest(w, r)
 	default:
 		fs.writeError(w, r, "Method not allowed", http.StatusMethodNotAllowed,
 			fmt.Sprintf("Method '%s' is not supported", r.Method))
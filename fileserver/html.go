@@ -0,0 +1,110 @@
+package fileserver
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"path"
+	"strings"
+)
+
+//go:embed templates/directory.html
+var templatesFS embed.FS
+
+var directoryTemplate = template.Must(
+	template.New("directory.html").
+		Funcs(template.FuncMap{
+			"humanSize": humanSize,
+			"icon":      fileIcon,
+		}).
+		ParseFS(templatesFS, "templates/directory.html"),
+)
+
+type breadcrumb struct {
+	Name string
+	Path string
+}
+
+type directoryView struct {
+	DirectoryResponse
+	Breadcrumbs []breadcrumb
+}
+
+// acceptsHTML reports whether the client's Accept header prefers
+// text/html over application/json, based on which is listed first.
+func acceptsHTML(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		switch strings.TrimSpace(strings.SplitN(part, ";", 2)[0]) {
+		case "text/html":
+			return true
+		case "application/json", "*/*", "":
+			return false
+		}
+	}
+	return false
+}
+
+func renderDirectoryHTML(w http.ResponseWriter, resp DirectoryResponse) error {
+	view := directoryView{
+		DirectoryResponse: resp,
+		Breadcrumbs:       breadcrumbs(resp.Path),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	return directoryTemplate.Execute(w, view)
+}
+
+func breadcrumbs(dirPath string) []breadcrumb {
+	crumbs := []breadcrumb{{Name: "/", Path: "/"}}
+
+	segments := strings.Split(strings.Trim(dirPath, "/"), "/")
+	built := ""
+	for _, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		built = path.Join(built, segment)
+		crumbs = append(crumbs, breadcrumb{Name: segment, Path: "/" + built})
+	}
+
+	return crumbs
+}
+
+func humanSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// fileIcon returns a short CSS class the template uses to pick a
+// background icon for the entry, based on its MIME type.
+func fileIcon(f FileInfo) string {
+	if f.IsDir {
+		return "icon-dir"
+	}
+
+	switch {
+	case strings.HasPrefix(f.MimeType, "image/"):
+		return "icon-image"
+	case strings.HasPrefix(f.MimeType, "video/"):
+		return "icon-video"
+	case strings.HasPrefix(f.MimeType, "audio/"):
+		return "icon-audio"
+	case strings.HasPrefix(f.MimeType, "text/"):
+		return "icon-text"
+	case strings.Contains(f.MimeType, "zip") || strings.Contains(f.MimeType, "tar"):
+		return "icon-archive"
+	default:
+		return "icon-file"
+	}
+}
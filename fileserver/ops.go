@@ -0,0 +1,228 @@
+package fileserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// @Summary Perform a filesystem operation
+// @Description Create a directory, rename/copy/move a file or directory, or batch delete, selected via the "op" query parameter
+// @Tags files
+// @Param op query string true "Operation" Enums(mkdir,rename,copy,move,batchDelete)
+// @Param path path string false "Source path (mkdir, rename, copy, move)"
+// @Param to query string false "Destination path (rename, copy, move)"
+// @Accept json
+// @Produce json
+// @Success 200 {object} UploadResponse "Rename or move succeeded"
+// @Success 201 {object} UploadResponse "Directory created or copy made"
+// @Success 200 {object} BatchDeleteResponse "Batch delete succeeded"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "File not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /files/{path} [post]
+func (fs *FileServer) postRequest(w http.ResponseWriter, r *http.Request) {
+	if fs.readOnly {
+		fs.writeError(w, r, "Server is read-only", http.StatusForbidden, "Write operations are disabled")
+		return
+	}
+
+	switch r.URL.Query().Get("op") {
+	case "mkdir":
+		fs.mkdirOp(w, r)
+	case "rename":
+		fs.renameOp(w, r)
+	case "copy":
+		fs.copyOp(w, r)
+	case "move":
+		fs.moveOp(w, r)
+	case "batchDelete":
+		fs.batchDeleteOp(w, r)
+	case "compress":
+		fs.compressOp(w, r)
+	case "decompress":
+		fs.decompressOp(w, r)
+	case "createUpload":
+		fs.createUploadOp(w, r)
+	case "reindex":
+		fs.reindexOp(w, r)
+	default:
+		fs.writeError(w, r, "Unknown operation", http.StatusBadRequest,
+			fmt.Sprintf("op '%s' is not supported", r.URL.Query().Get("op")))
+	}
+}
+
+func (fs *FileServer) mkdirOp(w http.ResponseWriter, r *http.Request) {
+	key, err := fs.securePath(r.URL.Path)
+	if err != nil {
+		fs.writeError(w, r, "Invalid path", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := fs.backend.Mkdir(key); err != nil {
+		fs.writeError(w, r, "Cannot create directory", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	fs.logRequest(r, http.StatusCreated, fmt.Sprintf("created directory: %s", r.URL.Path))
+	fs.writeOpSuccess(w, r, "Directory created successfully", r.URL.Path, http.StatusCreated)
+}
+
+func (fs *FileServer) renameOp(w http.ResponseWriter, r *http.Request) {
+	fs.moveLike(w, r, "Renamed successfully")
+}
+
+func (fs *FileServer) moveOp(w http.ResponseWriter, r *http.Request) {
+	fs.moveLike(w, r, "Moved successfully")
+}
+
+func (fs *FileServer) moveLike(w http.ResponseWriter, r *http.Request, successMessage string) {
+	if !fs.allowDelete {
+		fs.writeError(w, r, "Delete operations not allowed", http.StatusForbidden, "Delete operations are disabled by configuration")
+		return
+	}
+
+	srcKey, dstKey, ok := fs.resolveSrcDst(w, r)
+	if !ok {
+		return
+	}
+
+	if err := fs.backend.Rename(srcKey, dstKey); err != nil {
+		fs.writeError(w, r, "Cannot move", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	fs.logRequest(r, http.StatusOK, fmt.Sprintf("%s: %s -> %s", successMessage, r.URL.Path, r.URL.Query().Get("to")))
+	fs.writeOpSuccess(w, r, successMessage, r.URL.Query().Get("to"), http.StatusOK)
+}
+
+func (fs *FileServer) copyOp(w http.ResponseWriter, r *http.Request) {
+	srcKey, dstKey, ok := fs.resolveSrcDst(w, r)
+	if !ok {
+		return
+	}
+
+	if err := fs.backend.Copy(srcKey, dstKey); err != nil {
+		fs.writeError(w, r, "Cannot copy", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	fs.logRequest(r, http.StatusCreated, fmt.Sprintf("copied: %s -> %s", r.URL.Path, r.URL.Query().Get("to")))
+	fs.writeOpSuccess(w, r, "Copied successfully", r.URL.Query().Get("to"), http.StatusCreated)
+}
+
+func (fs *FileServer) resolveSrcDst(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	to := r.URL.Query().Get("to")
+	if to == "" {
+		fs.writeError(w, r, "Missing destination", http.StatusBadRequest, "query parameter 'to' is required")
+		return "", "", false
+	}
+
+	srcKey, err := fs.securePath(r.URL.Path)
+	if err != nil {
+		fs.writeError(w, r, "Invalid source path", http.StatusBadRequest, err.Error())
+		return "", "", false
+	}
+
+	dstKey, err := fs.securePath(to)
+	if err != nil {
+		fs.writeError(w, r, "Invalid destination path", http.StatusBadRequest, err.Error())
+		return "", "", false
+	}
+
+	if err := fs.authorizePath(r, srcKey, true); err != nil {
+		fs.writeError(w, r, "Access denied", http.StatusForbidden, err.Error())
+		return "", "", false
+	}
+	if err := fs.authorizePath(r, dstKey, true); err != nil {
+		fs.writeError(w, r, "Access denied", http.StatusForbidden, err.Error())
+		return "", "", false
+	}
+
+	if !fs.backend.Exists(srcKey) {
+		fs.writeError(w, r, "File not found", http.StatusNotFound, fmt.Sprintf("'%s' does not exist", r.URL.Path))
+		return "", "", false
+	}
+
+	return srcKey, dstKey, true
+}
+
+func (fs *FileServer) batchDeleteOp(w http.ResponseWriter, r *http.Request) {
+	if !fs.allowDelete {
+		fs.writeError(w, r, "Delete operations not allowed", http.StatusForbidden, "Delete operations are disabled by configuration")
+		return
+	}
+
+	var paths []string
+	if err := json.NewDecoder(r.Body).Decode(&paths); err != nil {
+		fs.writeError(w, r, "Invalid request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	deleted := make([]string, 0, len(paths))
+	for _, p := range paths {
+		key, err := fs.securePath(p)
+		if err != nil {
+			fs.writeBatchDeleteError(w, r, deleted, "Invalid path", http.StatusBadRequest, fmt.Sprintf("'%s': %s", p, err.Error()), p)
+			return
+		}
+
+		if err := fs.authorizePath(r, key, true); err != nil {
+			fs.writeBatchDeleteError(w, r, deleted, "Access denied", http.StatusForbidden, err.Error(), p)
+			return
+		}
+
+		if err := fs.backend.Delete(key, true); err != nil {
+			fs.writeBatchDeleteError(w, r, deleted, "Cannot delete", http.StatusInternalServerError, fmt.Sprintf("'%s': %s", p, err.Error()), p)
+			return
+		}
+		deleted = append(deleted, p)
+	}
+
+	fs.logRequest(r, http.StatusOK, fmt.Sprintf("batch deleted %d paths", len(deleted)))
+
+	response := BatchDeleteResponse{
+		Message:   "Successfully deleted",
+		Deleted:   deleted,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeBatchDeleteError writes an error response for a batchDelete
+// operation that failed partway through, including the paths deleted
+// before the failure so the caller knows which ones are already gone.
+func (fs *FileServer) writeBatchDeleteError(w http.ResponseWriter, r *http.Request, deleted []string, message string, statusCode int, details, path string) {
+	fs.logRequest(r, statusCode, fmt.Sprintf("%s: %s", message, details))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := BatchDeleteErrorResponse{
+		ErrorResponse: ErrorResponse{
+			Error:     http.StatusText(statusCode),
+			Message:   message,
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Path:      path,
+		},
+		Deleted: deleted,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func (fs *FileServer) writeOpSuccess(w http.ResponseWriter, r *http.Request, message, path string, status int) {
+	response := UploadResponse{
+		Message:   message,
+		Path:      path,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,288 @@
+package fileserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// uploadSession tracks an in-progress tus-style resumable upload. It is
+// kept in memory only; a restart of the process abandons any upload in
+// flight, which callers are expected to retry with a fresh createUpload.
+type uploadSession struct {
+	destKey string
+	tempKey string
+	offset  int64
+	total   int64
+	expires time.Time
+}
+
+const uploadSessionTTL = time.Hour
+
+// uploadsDir holds the temp files backing in-progress resumable uploads.
+// It is excluded from directory listings, search, and direct GET/PUT/
+// DELETE access so a client can't list, download, or tamper with another
+// upload's partial content.
+const uploadsDir = ".uploads"
+
+// uploadReapInterval controls how often expired upload sessions are
+// purged, including the temp file backing them.
+const uploadReapInterval = 5 * time.Minute
+
+// runUploadReaper periodically removes expired upload sessions. Without
+// this, an abandoned upload's in-memory session and its temp file are only
+// ever removed the next time something PATCHes it.
+func (fs *FileServer) runUploadReaper() {
+	ticker := time.NewTicker(uploadReapInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		fs.reapExpiredUploads()
+	}
+}
+
+func (fs *FileServer) reapExpiredUploads() {
+	now := time.Now()
+
+	fs.uploadsMu.Lock()
+	var expired []*uploadSession
+	for id, session := range fs.uploads {
+		if now.After(session.expires) {
+			expired = append(expired, session)
+			delete(fs.uploads, id)
+		}
+	}
+	fs.uploadsMu.Unlock()
+
+	for _, session := range expired {
+		fs.backend.Delete(session.tempKey, false)
+	}
+}
+
+// parseContentRange parses a "bytes start-end/total" Content-Range header
+// value as sent by a PUT request resuming a partial upload.
+func parseContentRange(header string) (start, end, total int64, err error) {
+	const prefix = "bytes "
+	if !strings.HasPrefix(header, prefix) {
+		return 0, 0, 0, errors.New("Content-Range must start with 'bytes '")
+	}
+
+	rangeAndTotal := strings.SplitN(strings.TrimPrefix(header, prefix), "/", 2)
+	if len(rangeAndTotal) != 2 {
+		return 0, 0, 0, errors.New("Content-Range must be of the form 'bytes start-end/total'")
+	}
+
+	startEnd := strings.SplitN(rangeAndTotal[0], "-", 2)
+	if len(startEnd) != 2 {
+		return 0, 0, 0, errors.New("Content-Range must be of the form 'bytes start-end/total'")
+	}
+
+	start, err = strconv.ParseInt(startEnd[0], 10, 64)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid range start: %w", err)
+	}
+	end, err = strconv.ParseInt(startEnd[1], 10, 64)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid range end: %w", err)
+	}
+	total, err = strconv.ParseInt(rangeAndTotal[1], 10, 64)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid range total: %w", err)
+	}
+	if start < 0 || end < start || total < end+1 {
+		return 0, 0, 0, errors.New("Content-Range values are out of order")
+	}
+
+	return start, end, total, nil
+}
+
+func newUploadID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// @Summary Start a resumable upload
+// @Description Reserve an upload id for sequential PATCH requests
+// @Tags files
+// @Accept json
+// @Produce json
+// @Success 201 {object} map[string]string "Upload id and URL"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 413 {object} ErrorResponse "File too large"
+// @Router /files/{path} [post]
+func (fs *FileServer) createUploadOp(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Path string `json:"path"`
+		Size int64  `json:"size"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fs.writeError(w, r, "Invalid request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.Size > fs.maxFileSize {
+		fs.writeError(w, r, "File too large", http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("File size %d exceeds maximum %d", req.Size, fs.maxFileSize))
+		return
+	}
+
+	destKey, err := fs.securePath(req.Path)
+	if err != nil {
+		fs.writeError(w, r, "Invalid path", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := fs.authorizePath(r, destKey, true); err != nil {
+		fs.writeError(w, r, "Access denied", http.StatusForbidden, err.Error())
+		return
+	}
+
+	id, err := newUploadID()
+	if err != nil {
+		fs.writeError(w, r, "Cannot create upload", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session := &uploadSession{
+		destKey: destKey,
+		tempKey: ".uploads/" + id + ".part",
+		total:   req.Size,
+		expires: time.Now().Add(uploadSessionTTL),
+	}
+
+	fs.uploadsMu.Lock()
+	fs.uploads[id] = session
+	fs.uploadsMu.Unlock()
+
+	fs.logRequest(r, http.StatusCreated, fmt.Sprintf("created resumable upload %s for %s (%d bytes)", id, req.Path, req.Size))
+
+	w.Header().Set("Upload-Offset", "0")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{
+		"uploadId":  id,
+		"uploadUrl": fmt.Sprintf("/?op=upload&id=%s", id),
+	})
+}
+
+// @Summary Append a chunk to a resumable upload
+// @Description Append bytes at Upload-Offset to the upload identified by "id"; promotes the file once Upload-Length is reached
+// @Tags files
+// @Param id query string true "Upload id"
+// @Param Upload-Offset header string true "Offset of this chunk"
+// @Param Upload-Length header string false "Total expected size; when reached the upload is finalized"
+// @Produce json
+// @Success 204 "Chunk accepted"
+// @Success 201 {object} UploadResponse "Upload finalized"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 404 {object} ErrorResponse "Unknown or expired upload"
+// @Failure 409 {object} ErrorResponse "Offset mismatch"
+// @Router /?op=upload [patch]
+func (fs *FileServer) patchRequest(w http.ResponseWriter, r *http.Request) {
+	if fs.readOnly {
+		fs.writeError(w, r, "Server is read-only", http.StatusForbidden, "Write operations are disabled")
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		fs.writeError(w, r, "Missing upload id", http.StatusBadRequest, "query parameter 'id' is required")
+		return
+	}
+
+	fs.uploadsMu.Lock()
+	session, ok := fs.uploads[id]
+	fs.uploadsMu.Unlock()
+
+	if !ok {
+		fs.writeError(w, r, "Unknown upload", http.StatusNotFound, fmt.Sprintf("upload '%s' does not exist or has expired", id))
+		return
+	}
+	if time.Now().After(session.expires) {
+		fs.uploadsMu.Lock()
+		delete(fs.uploads, id)
+		fs.uploadsMu.Unlock()
+		fs.backend.Delete(session.tempKey, false)
+		fs.writeError(w, r, "Upload expired", http.StatusNotFound, fmt.Sprintf("upload '%s' has expired", id))
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		fs.writeError(w, r, "Missing Upload-Offset", http.StatusBadRequest, "header 'Upload-Offset' is required")
+		return
+	}
+	if offset != session.offset {
+		fs.writeError(w, r, "Offset mismatch", http.StatusConflict,
+			fmt.Sprintf("expected offset %d, got %d", session.offset, offset))
+		return
+	}
+
+	if session.offset+r.ContentLength > fs.maxFileSize {
+		fs.writeError(w, r, "File too large", http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("File size exceeds maximum %d", fs.maxFileSize))
+		return
+	}
+
+	written, err := fs.backend.WriteAt(session.tempKey, r.Body, session.offset)
+	if err != nil {
+		fs.writeError(w, r, "Cannot write chunk", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	fs.uploadsMu.Lock()
+	session.offset += written
+	newOffset := session.offset
+	fs.uploadsMu.Unlock()
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(newOffset, 10))
+
+	uploadLength := session.total
+	if header := r.Header.Get("Upload-Length"); header != "" {
+		if parsed, err := strconv.ParseInt(header, 10, 64); err == nil {
+			uploadLength = parsed
+		}
+	}
+
+	if uploadLength > 0 && newOffset >= uploadLength {
+		if err := fs.authorizePath(r, session.destKey, true); err != nil {
+			fs.writeError(w, r, "Access denied", http.StatusForbidden, err.Error())
+			return
+		}
+
+		if err := fs.backend.Rename(session.tempKey, session.destKey); err != nil {
+			fs.writeError(w, r, "Cannot finalize upload", http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		fs.uploadsMu.Lock()
+		delete(fs.uploads, id)
+		fs.uploadsMu.Unlock()
+
+		fs.logRequest(r, http.StatusCreated, fmt.Sprintf("finalized resumable upload %s (%d bytes)", id, newOffset))
+
+		response := UploadResponse{
+			Message:   "File created successfully",
+			Path:      session.destKey,
+			Size:      newOffset,
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	fs.logRequest(r, http.StatusNoContent, fmt.Sprintf("appended chunk to upload %s (offset now %d)", id, newOffset))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,126 @@
+package fileserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func newResumableTestServer(t *testing.T) (*FileServer, StorageBackend) {
+	t.Helper()
+	backend := NewMemoryBackend()
+	fs := New(WithStorageBackend(backend), WithMaxFileSize(1<<20))
+	return fs, backend
+}
+
+func TestResumableUploadRoundTrip(t *testing.T) {
+	fs, backend := newResumableTestServer(t)
+
+	createBody, _ := json.Marshal(map[string]any{"path": "/big.bin", "size": 10})
+	createReq := httptest.NewRequest(http.MethodPost, "/?op=createUpload", bytes.NewReader(createBody))
+	createRec := httptest.NewRecorder()
+	fs.ServeHTTP(createRec, createReq)
+
+	if createRec.Code != http.StatusCreated {
+		t.Fatalf("createUpload: got status %d: %s", createRec.Code, createRec.Body.String())
+	}
+
+	var created struct {
+		UploadID string `json:"uploadId"`
+	}
+	if err := json.Unmarshal(createRec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("unmarshal createUpload response: %v", err)
+	}
+
+	chunk1 := []byte("hello")
+	req1 := httptest.NewRequest(http.MethodPatch, "/?op=upload&id="+created.UploadID, bytes.NewReader(chunk1))
+	req1.Header.Set("Upload-Offset", "0")
+	req1.ContentLength = int64(len(chunk1))
+	rec1 := httptest.NewRecorder()
+	fs.ServeHTTP(rec1, req1)
+	if rec1.Code != http.StatusNoContent {
+		t.Fatalf("first PATCH: got status %d: %s", rec1.Code, rec1.Body.String())
+	}
+
+	chunk2 := []byte("world")
+	req2 := httptest.NewRequest(http.MethodPatch, "/?op=upload&id="+created.UploadID, bytes.NewReader(chunk2))
+	req2.Header.Set("Upload-Offset", strconv.Itoa(len(chunk1)))
+	req2.Header.Set("Upload-Length", "10")
+	req2.ContentLength = int64(len(chunk2))
+	rec2 := httptest.NewRecorder()
+	fs.ServeHTTP(rec2, req2)
+	if rec2.Code != http.StatusCreated {
+		t.Fatalf("final PATCH: got status %d: %s", rec2.Code, rec2.Body.String())
+	}
+
+	file, err := backend.Open("big.bin")
+	if err != nil {
+		t.Fatalf("Open finalized file: %v", err)
+	}
+	defer file.Close()
+
+	var out bytes.Buffer
+	if _, err := out.ReadFrom(file); err != nil {
+		t.Fatalf("read finalized file: %v", err)
+	}
+	if out.String() != "helloworld" {
+		t.Fatalf("got content %q, want %q", out.String(), "helloworld")
+	}
+}
+
+// TestResumableUploadEnforcesACL makes sure a user denied access to a
+// directory can't use the resumable-upload flow to write into it, even
+// though a direct PUT to the same path is already rejected.
+func TestResumableUploadEnforcesACL(t *testing.T) {
+	backend := NewMemoryBackend()
+	if _, err := backend.Put("secret/.ghs.yml", bytes.NewReader([]byte("deny: [bob]\n"))); err != nil {
+		t.Fatalf("Put secret/.ghs.yml: %v", err)
+	}
+
+	fs := New(WithStorageBackend(backend), WithAuth(headerAuthenticator{}), WithMaxFileSize(1<<20))
+
+	createBody, _ := json.Marshal(map[string]any{"path": "/secret/pwned.txt", "size": 5})
+	createRec := doAs(t, fs, http.MethodPost, "/?op=createUpload", "bob", createBody)
+	if createRec.Code != http.StatusForbidden {
+		t.Fatalf("createUpload: got status %d, want %d: %s", createRec.Code, http.StatusForbidden, createRec.Body.String())
+	}
+
+	if backend.Exists("secret/pwned.txt") {
+		t.Fatal("denied user's resumable upload should not have created the file")
+	}
+}
+
+// TestUploadsDirHiddenFromListing makes sure an in-progress upload's temp
+// file in .uploads isn't visible in the root directory listing and can't
+// be fetched with a direct GET.
+func TestUploadsDirHiddenFromListing(t *testing.T) {
+	fs, backend := newResumableTestServer(t)
+
+	if _, err := backend.WriteAt(".uploads/secret.part", bytes.NewReader([]byte("in progress")), 0); err != nil {
+		t.Fatalf("WriteAt: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	fs.ServeHTTP(rec, req)
+
+	var listing DirectoryResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &listing); err != nil {
+		t.Fatalf("unmarshal directory listing: %v", err)
+	}
+	for _, f := range listing.Files {
+		if f.Name == uploadsDir {
+			t.Fatalf("listing leaked %s: %+v", uploadsDir, listing.Files)
+		}
+	}
+
+	getReq := httptest.NewRequest(http.MethodGet, "/.uploads/secret.part", nil)
+	getRec := httptest.NewRecorder()
+	fs.ServeHTTP(getRec, getReq)
+	if getRec.Code == http.StatusOK {
+		t.Fatalf("expected direct GET of an upload temp file to be rejected, got %d", getRec.Code)
+	}
+}
@@ -0,0 +1,190 @@
+package fileserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// indexEntry is a single entry in the background search index: the path
+// relative to the data root, and the file metadata observed for it.
+type indexEntry struct {
+	Path string
+	Info os.FileInfo
+}
+
+// pathBuilderPool reuses bytes.Buffers when assembling index paths so
+// that rebuilding the index on a large tree doesn't churn the allocator.
+var pathBuilderPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// runIndexer builds the search index immediately, then keeps it fresh on
+// fs.indexInterval until the process exits.
+func (fs *FileServer) runIndexer() {
+	fs.rebuildIndex()
+
+	ticker := time.NewTicker(fs.indexInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		fs.rebuildIndex()
+	}
+}
+
+func (fs *FileServer) rebuildIndex() {
+	var entries []indexEntry
+	fs.walkIndex(".", &entries)
+
+	fs.indexMu.Lock()
+	fs.index = entries
+	fs.indexMu.Unlock()
+}
+
+func (fs *FileServer) walkIndex(key string, entries *[]indexEntry) {
+	infos, err := fs.backend.List(key)
+	if err != nil {
+		return
+	}
+
+	for _, info := range infos {
+		if key == "." && info.Name() == uploadsDir {
+			continue
+		}
+		if info.Name() == aclFileName {
+			continue
+		}
+
+		sb := pathBuilderPool.Get().(*bytes.Buffer)
+		sb.Reset()
+		if key != "." {
+			sb.WriteString(key)
+			sb.WriteByte('/')
+		}
+		sb.WriteString(info.Name())
+		childPath := sb.String()
+		pathBuilderPool.Put(sb)
+
+		*entries = append(*entries, indexEntry{Path: childPath, Info: info})
+
+		if info.IsDir() {
+			fs.walkIndex(childPath, entries)
+		}
+	}
+}
+
+// @Summary Search the data directory
+// @Description Search the background-indexed data directory by name substring, glob pattern, and/or extension
+// @Tags files
+// @Param q query string false "Substring or glob pattern to match against the file name"
+// @Param ext query string false "Extension filter, e.g. '.go'"
+// @Param limit query int false "Maximum number of results"
+// @Produce json
+// @Success 200 {object} DirectoryResponse "Matching files"
+// @Router /?op=search [get]
+func (fs *FileServer) searchOp(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	ext := r.URL.Query().Get("ext")
+	lowerQuery := strings.ToLower(query)
+
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	fs.indexMu.RLock()
+	snapshot := fs.index
+	fs.indexMu.RUnlock()
+
+	var files []FileInfo
+	var totalSize int64
+	matchCount := 0
+
+	for _, entry := range snapshot {
+		name := entry.Info.Name()
+
+		if query != "" {
+			matched, _ := filepath.Match(query, name)
+			if !matched && !strings.Contains(strings.ToLower(name), lowerQuery) {
+				continue
+			}
+		}
+
+		if ext != "" && filepath.Ext(name) != ext {
+			continue
+		}
+
+		if fs.authorizePath(r, entry.Path, false) != nil {
+			continue
+		}
+
+		matchCount++
+
+		if limit > 0 && len(files) >= limit {
+			continue
+		}
+
+		fileInfo := FileInfo{
+			Name:    name,
+			IsDir:   entry.Info.IsDir(),
+			Size:    entry.Info.Size(),
+			ModTime: entry.Info.ModTime().UTC().Format(time.RFC3339),
+			Path:    "/" + entry.Path,
+		}
+
+		if !entry.Info.IsDir() {
+			if mimeType := mime.TypeByExtension(filepath.Ext(name)); mimeType != "" {
+				fileInfo.MimeType = mimeType
+			}
+			totalSize += entry.Info.Size()
+		}
+
+		files = append(files, fileInfo)
+	}
+
+	response := DirectoryResponse{
+		Path:       "/",
+		Files:      files,
+		TotalSize:  totalSize,
+		Count:      len(files),
+		TotalCount: matchCount,
+	}
+
+	fs.logRequest(r, http.StatusOK, fmt.Sprintf("search %q matched %d results", query, matchCount))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// @Summary Rebuild the search index
+// @Description Force an immediate rebuild of the background search index
+// @Tags files
+// @Produce json
+// @Success 200 {object} map[string]any "Index rebuilt"
+// @Router /?op=reindex [post]
+func (fs *FileServer) reindexOp(w http.ResponseWriter, r *http.Request) {
+	fs.rebuildIndex()
+
+	fs.indexMu.RLock()
+	count := len(fs.index)
+	fs.indexMu.RUnlock()
+
+	fs.logRequest(r, http.StatusOK, fmt.Sprintf("rebuilt search index (%d entries)", count))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"message":   "Index rebuilt successfully",
+		"count":     count,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,81 @@
+package fileserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestSearchEnforcesACL makes sure search results are filtered the same
+// way a direct GET would be, instead of leaking metadata straight from
+// the background index.
+func TestSearchEnforcesACL(t *testing.T) {
+	backend := NewMemoryBackend()
+	if _, err := backend.Put("secret/.ghs.yml", bytes.NewReader([]byte("deny: [bob]\n"))); err != nil {
+		t.Fatalf("Put secret/.ghs.yml: %v", err)
+	}
+	if _, err := backend.Put("secret/topsecret.txt", bytes.NewReader([]byte("shh"))); err != nil {
+		t.Fatalf("Put secret/topsecret.txt: %v", err)
+	}
+
+	fs := New(WithStorageBackend(backend), WithAuth(headerAuthenticator{}))
+	fs.rebuildIndex()
+
+	rec := doAs(t, fs, http.MethodGet, "/?op=search&q=topsecret", "bob", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("search: got status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp DirectoryResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal search response: %v", err)
+	}
+	for _, f := range resp.Files {
+		if f.Name == "topsecret.txt" {
+			t.Fatalf("search leaked denied file: %+v", resp.Files)
+		}
+	}
+}
+
+// TestAclFileHiddenFromListingAndSearch makes sure .ghs.yml never appears
+// by name in a directory listing or search results, the same way direct
+// GET of it is already blocked by securePath.
+func TestAclFileHiddenFromListingAndSearch(t *testing.T) {
+	backend := NewMemoryBackend()
+	if _, err := backend.Put(".ghs.yml", bytes.NewReader([]byte("allow: [alice]\n"))); err != nil {
+		t.Fatalf("Put .ghs.yml: %v", err)
+	}
+
+	fs := New(WithStorageBackend(backend))
+	fs.rebuildIndex()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	fs.ServeHTTP(rec, req)
+
+	var listing DirectoryResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &listing); err != nil {
+		t.Fatalf("unmarshal directory listing: %v", err)
+	}
+	for _, f := range listing.Files {
+		if f.Name == aclFileName {
+			t.Fatalf("listing leaked %s: %+v", aclFileName, listing.Files)
+		}
+	}
+
+	searchReq := httptest.NewRequest(http.MethodGet, "/?op=search&q=ghs", nil)
+	searchRec := httptest.NewRecorder()
+	fs.ServeHTTP(searchRec, searchReq)
+
+	var searchResp DirectoryResponse
+	if err := json.Unmarshal(searchRec.Body.Bytes(), &searchResp); err != nil {
+		t.Fatalf("unmarshal search response: %v", err)
+	}
+	for _, f := range searchResp.Files {
+		if f.Name == aclFileName {
+			t.Fatalf("search leaked %s: %+v", aclFileName, searchResp.Files)
+		}
+	}
+}
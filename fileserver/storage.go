@@ -0,0 +1,59 @@
+package fileserver
+
+import (
+	"io"
+	"net/http"
+	"os"
+)
+
+// StorageBackend abstracts the storage medium FileServer operates on.
+// Implementations translate the handler-level operations (list, read,
+// write, delete, serve) into calls against local disk, an object store,
+// or an in-memory map, so the same HTTP surface can be backed by
+// whichever is appropriate without touching the handlers themselves.
+//
+// Paths passed to a StorageBackend are always keys relative to the
+// server's data root, already validated by securePath.
+type StorageBackend interface {
+	// Stat returns file metadata for path, or an error satisfying
+	// os.IsNotExist if it does not exist.
+	Stat(path string) (os.FileInfo, error)
+
+	// List returns the entries directly inside the directory at path.
+	List(path string) ([]os.FileInfo, error)
+
+	// Open returns a reader for the file at path. The caller must close it.
+	Open(path string) (io.ReadCloser, error)
+
+	// Put writes the contents of r to key, creating or overwriting it,
+	// and returns the number of bytes written.
+	Put(key string, r io.Reader) (int64, error)
+
+	// Delete removes the file or directory at path. If recursive is
+	// true and path is a directory, its contents are removed as well.
+	Delete(path string, recursive bool) error
+
+	// ServeFile writes the file at path to w. The local-disk and
+	// in-memory backends handle range requests, conditional requests,
+	// and content-type detection; the S3 backend copies the object body
+	// as is and handles none of these.
+	ServeFile(w http.ResponseWriter, r *http.Request, path string) error
+
+	// Exists reports whether path refers to an existing file or directory.
+	Exists(path string) bool
+
+	// Mkdir creates the directory at path, along with any necessary
+	// parents, similar to os.MkdirAll.
+	Mkdir(path string) error
+
+	// Rename moves the file or directory at src to dst.
+	Rename(src, dst string) error
+
+	// Copy copies the file or directory at src to dst.
+	Copy(src, dst string) error
+
+	// WriteAt writes the contents of r to key starting at offset,
+	// creating key if it does not exist, and returns the number of
+	// bytes written. It supports range/resumable uploads.
+	WriteAt(key string, r io.Reader, offset int64) (int64, error)
+}
@@ -0,0 +1,160 @@
+package fileserver
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// localFS is the default StorageBackend, backing the file server with a
+// directory on local disk. It mirrors the behavior FileServer had before
+// StorageBackend was introduced.
+type localFS struct {
+	root string
+}
+
+func newLocalFS(root string) *localFS {
+	return &localFS{root: root}
+}
+
+func (l *localFS) resolve(path string) string {
+	return filepath.Join(l.root, path)
+}
+
+func (l *localFS) Stat(path string) (os.FileInfo, error) {
+	return os.Stat(l.resolve(path))
+}
+
+func (l *localFS) List(path string) ([]os.FileInfo, error) {
+	entries, err := os.ReadDir(l.resolve(path))
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (l *localFS) Open(path string) (io.ReadCloser, error) {
+	return os.Open(l.resolve(path))
+}
+
+func (l *localFS) Put(key string, r io.Reader) (int64, error) {
+	fullPath := l.resolve(key)
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return 0, err
+	}
+
+	file, err := os.Create(fullPath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	return io.Copy(file, r)
+}
+
+func (l *localFS) Delete(path string, recursive bool) error {
+	fullPath := l.resolve(path)
+	if recursive {
+		return os.RemoveAll(fullPath)
+	}
+	return os.Remove(fullPath)
+}
+
+func (l *localFS) ServeFile(w http.ResponseWriter, r *http.Request, path string) error {
+	http.ServeFile(w, r, l.resolve(path))
+	return nil
+}
+
+func (l *localFS) Exists(path string) bool {
+	_, err := os.Stat(l.resolve(path))
+	return err == nil
+}
+
+func (l *localFS) Mkdir(path string) error {
+	return os.MkdirAll(l.resolve(path), 0755)
+}
+
+func (l *localFS) Rename(src, dst string) error {
+	fullDst := l.resolve(dst)
+	if err := os.MkdirAll(filepath.Dir(fullDst), 0755); err != nil {
+		return err
+	}
+	return os.Rename(l.resolve(src), fullDst)
+}
+
+func (l *localFS) Copy(src, dst string) error {
+	info, err := os.Stat(l.resolve(src))
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		return filepath.WalkDir(l.resolve(src), func(p string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(l.resolve(src), p)
+			if err != nil {
+				return err
+			}
+			target := filepath.Join(l.resolve(dst), rel)
+			if d.IsDir() {
+				return os.MkdirAll(target, 0755)
+			}
+			return copyFile(p, target)
+		})
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.resolve(dst)), 0755); err != nil {
+		return err
+	}
+	return copyFile(l.resolve(src), l.resolve(dst))
+}
+
+func (l *localFS) WriteAt(key string, r io.Reader, offset int64) (int64, error) {
+	fullPath := l.resolve(key)
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return 0, err
+	}
+
+	file, err := os.OpenFile(fullPath, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	return io.Copy(file, r)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,311 @@
+package fileserver
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryFS is a StorageBackend that keeps everything in process memory.
+// It exists for tests that want a fast, hermetic FileServer without
+// touching disk or a real object store.
+type memoryFS struct {
+	mu      sync.RWMutex
+	entries map[string]*memoryEntry
+}
+
+type memoryEntry struct {
+	data    []byte
+	isDir   bool
+	modTime time.Time
+}
+
+// memoryFileInfo adapts a memoryEntry to os.FileInfo.
+type memoryFileInfo struct {
+	name  string
+	entry *memoryEntry
+}
+
+func (fi memoryFileInfo) Name() string       { return fi.name }
+func (fi memoryFileInfo) Size() int64        { return int64(len(fi.entry.data)) }
+func (fi memoryFileInfo) ModTime() time.Time { return fi.entry.modTime }
+func (fi memoryFileInfo) IsDir() bool        { return fi.entry.isDir }
+func (fi memoryFileInfo) Sys() any           { return nil }
+
+func (fi memoryFileInfo) Mode() os.FileMode {
+	if fi.entry.isDir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+
+func newMemoryFS() *memoryFS {
+	return &memoryFS{
+		entries: map[string]*memoryEntry{
+			".": {isDir: true, modTime: time.Now()},
+		},
+	}
+}
+
+// NewMemoryBackend returns a StorageBackend that keeps everything in
+// process memory, for use with WithStorageBackend in tests that want a
+// fast, hermetic FileServer without touching disk or a real object store.
+func NewMemoryBackend() StorageBackend {
+	return newMemoryFS()
+}
+
+func normalizeKey(key string) string {
+	key = strings.TrimPrefix(path.Clean("/"+key), "/")
+	if key == "" {
+		return "."
+	}
+	return key
+}
+
+func (m *memoryFS) ensureDirs(key string) {
+	dir := path.Dir(key)
+	for dir != "." && dir != "/" {
+		if _, ok := m.entries[dir]; !ok {
+			m.entries[dir] = &memoryEntry{isDir: true, modTime: time.Now()}
+		}
+		dir = path.Dir(dir)
+	}
+	if _, ok := m.entries["."]; !ok {
+		m.entries["."] = &memoryEntry{isDir: true, modTime: time.Now()}
+	}
+}
+
+func (m *memoryFS) Stat(key string) (os.FileInfo, error) {
+	key = normalizeKey(key)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return memoryFileInfo{name: path.Base(key), entry: entry}, nil
+}
+
+func (m *memoryFS) List(key string) ([]os.FileInfo, error) {
+	key = normalizeKey(key)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	dir, ok := m.entries[key]
+	if !ok || !dir.isDir {
+		return nil, os.ErrNotExist
+	}
+
+	var infos []os.FileInfo
+	for candidate, entry := range m.entries {
+		if candidate == key || path.Dir(candidate) != key {
+			continue
+		}
+		infos = append(infos, memoryFileInfo{name: path.Base(candidate), entry: entry})
+	}
+	return infos, nil
+}
+
+func (m *memoryFS) Open(key string) (io.ReadCloser, error) {
+	key = normalizeKey(key)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, ok := m.entries[key]
+	if !ok || entry.isDir {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(entry.data)), nil
+}
+
+func (m *memoryFS) Put(key string, r io.Reader) (int64, error) {
+	key = normalizeKey(key)
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ensureDirs(key)
+	m.entries[key] = &memoryEntry{data: data, modTime: time.Now()}
+	return int64(len(data)), nil
+}
+
+func (m *memoryFS) Delete(key string, recursive bool) error {
+	key = normalizeKey(key)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return os.ErrNotExist
+	}
+
+	if entry.isDir {
+		hasChildren := false
+		for candidate := range m.entries {
+			if path.Dir(candidate) == key {
+				hasChildren = true
+				break
+			}
+		}
+		if hasChildren && !recursive {
+			return os.ErrInvalid
+		}
+		if recursive {
+			prefix := key + "/"
+			for candidate := range m.entries {
+				if strings.HasPrefix(candidate, prefix) {
+					delete(m.entries, candidate)
+				}
+			}
+		}
+	}
+
+	delete(m.entries, key)
+	return nil
+}
+
+func (m *memoryFS) ServeFile(w http.ResponseWriter, r *http.Request, key string) error {
+	key = normalizeKey(key)
+
+	m.mu.RLock()
+	entry, ok := m.entries[key]
+	m.mu.RUnlock()
+
+	if !ok || entry.isDir {
+		return os.ErrNotExist
+	}
+
+	http.ServeContent(w, r, path.Base(key), entry.modTime, bytes.NewReader(entry.data))
+	return nil
+}
+
+func (m *memoryFS) Exists(key string) bool {
+	key = normalizeKey(key)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.entries[key]
+	return ok
+}
+
+func (m *memoryFS) WriteAt(key string, r io.Reader, offset int64) (int64, error) {
+	key = normalizeKey(key)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ensureDirs(key)
+	entry, ok := m.entries[key]
+	if !ok {
+		entry = &memoryEntry{}
+		m.entries[key] = entry
+	}
+
+	var written int64
+	buf := make([]byte, 32*1024)
+	pos := offset
+
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			end := pos + int64(n)
+			if int64(len(entry.data)) < end {
+				grown := make([]byte, end)
+				copy(grown, entry.data)
+				entry.data = grown
+			}
+			copy(entry.data[pos:end], buf[:n])
+			pos = end
+			written += int64(n)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			entry.modTime = time.Now()
+			return written, err
+		}
+	}
+
+	entry.modTime = time.Now()
+	return written, nil
+}
+
+func (m *memoryFS) Mkdir(key string) error {
+	key = normalizeKey(key)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ensureDirs(key)
+	if _, ok := m.entries[key]; !ok {
+		m.entries[key] = &memoryEntry{isDir: true, modTime: time.Now()}
+	}
+	return nil
+}
+
+func (m *memoryFS) Rename(src, dst string) error {
+	src, dst = normalizeKey(src), normalizeKey(dst)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[src]
+	if !ok {
+		return os.ErrNotExist
+	}
+
+	m.ensureDirs(dst)
+	prefix := src + "/"
+	for candidate, e := range m.entries {
+		if strings.HasPrefix(candidate, prefix) {
+			m.entries[dst+"/"+strings.TrimPrefix(candidate, prefix)] = e
+			delete(m.entries, candidate)
+		}
+	}
+	m.entries[dst] = entry
+	delete(m.entries, src)
+	return nil
+}
+
+func (m *memoryFS) Copy(src, dst string) error {
+	src, dst = normalizeKey(src), normalizeKey(dst)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[src]
+	if !ok {
+		return os.ErrNotExist
+	}
+
+	m.ensureDirs(dst)
+	prefix := src + "/"
+	for candidate, e := range m.entries {
+		if strings.HasPrefix(candidate, prefix) {
+			copied := *e
+			copied.data = append([]byte(nil), e.data...)
+			m.entries[dst+"/"+strings.TrimPrefix(candidate, prefix)] = &copied
+		}
+	}
+	copied := *entry
+	copied.data = append([]byte(nil), entry.data...)
+	m.entries[dst] = &copied
+	return nil
+}
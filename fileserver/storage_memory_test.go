@@ -0,0 +1,119 @@
+package fileserver
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestMemoryBackendPutOpenDelete(t *testing.T) {
+	backend := NewMemoryBackend()
+
+	if _, err := backend.Put("dir/example.txt", strings.NewReader("hello")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if !backend.Exists("dir/example.txt") {
+		t.Fatal("expected file to exist after Put")
+	}
+
+	r, err := backend.Open("dir/example.txt")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(data) != "hello" {
+		t.Fatalf("got content %q, want %q", data, "hello")
+	}
+
+	infos, err := backend.List("dir")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(infos) != 1 || infos[0].Name() != "example.txt" {
+		t.Fatalf("List returned %v, want [example.txt]", infos)
+	}
+
+	if err := backend.Delete("dir/example.txt", false); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if backend.Exists("dir/example.txt") {
+		t.Fatal("expected file to be gone after Delete")
+	}
+}
+
+func TestMemoryBackendWriteAtStreamsInOrder(t *testing.T) {
+	backend := NewMemoryBackend()
+
+	if _, err := backend.WriteAt("example.txt", strings.NewReader("world"), 5); err != nil {
+		t.Fatalf("WriteAt tail: %v", err)
+	}
+	if _, err := backend.WriteAt("example.txt", strings.NewReader("hello"), 0); err != nil {
+		t.Fatalf("WriteAt head: %v", err)
+	}
+
+	r, err := backend.Open("example.txt")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(data) != "helloworld" {
+		t.Fatalf("got content %q, want %q", data, "helloworld")
+	}
+}
+
+// TestMemoryBackendCopyIsIndependentOfSource makes sure a Copy clones the
+// file content instead of sharing the source's backing array, so an
+// in-place WriteAt on the original can't mutate the copy.
+func TestMemoryBackendCopyIsIndependentOfSource(t *testing.T) {
+	backend := NewMemoryBackend()
+
+	if _, err := backend.Put("original.txt", strings.NewReader("0123456789")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := backend.Copy("original.txt", "copy.txt"); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	if _, err := backend.WriteAt("original.txt", strings.NewReader("XXXXX"), 0); err != nil {
+		t.Fatalf("WriteAt: %v", err)
+	}
+
+	r, err := backend.Open("copy.txt")
+	if err != nil {
+		t.Fatalf("Open copy: %v", err)
+	}
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(data) != "0123456789" {
+		t.Fatalf("got copy content %q after mutating source, want %q", data, "0123456789")
+	}
+}
+
+func TestMemoryBackendRenameMovesChildren(t *testing.T) {
+	backend := NewMemoryBackend()
+
+	if _, err := backend.Put("src/a.txt", strings.NewReader("a")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := backend.Rename("src", "dst"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+
+	if backend.Exists("src") || backend.Exists("src/a.txt") {
+		t.Fatal("expected source tree to be gone after Rename")
+	}
+	if !backend.Exists("dst/a.txt") {
+		t.Fatal("expected renamed child to exist at destination")
+	}
+}
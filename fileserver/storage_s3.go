@@ -0,0 +1,324 @@
+package fileserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// s3FS is a StorageBackend backed by an S3-compatible object store. Keys
+// are stored flat under prefix; directories are synthesized from common
+// key prefixes the same way the AWS console does.
+type s3FS struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+// NewS3Backend returns a StorageBackend that stores objects in bucket
+// under prefix, using client for all API calls.
+func NewS3Backend(client *s3.Client, bucket, prefix string) StorageBackend {
+	return &s3FS{client: client, bucket: bucket, prefix: strings.Trim(prefix, "/")}
+}
+
+type s3FileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	isDir   bool
+}
+
+func (fi s3FileInfo) Name() string       { return fi.name }
+func (fi s3FileInfo) Size() int64        { return fi.size }
+func (fi s3FileInfo) ModTime() time.Time { return fi.modTime }
+func (fi s3FileInfo) IsDir() bool        { return fi.isDir }
+func (fi s3FileInfo) Sys() any           { return nil }
+
+func (fi s3FileInfo) Mode() os.FileMode {
+	if fi.isDir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+
+func (b *s3FS) objectKey(key string) string {
+	key = strings.TrimPrefix(path.Clean("/"+key), "/")
+	if b.prefix == "" {
+		return key
+	}
+	if key == "." || key == "" {
+		return b.prefix
+	}
+	return b.prefix + "/" + key
+}
+
+func (b *s3FS) Stat(key string) (os.FileInfo, error) {
+	ctx := context.Background()
+	objKey := b.objectKey(key)
+
+	head, err := b.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(objKey),
+	})
+	if err == nil {
+		return s3FileInfo{
+			name:    path.Base(key),
+			size:    aws.ToInt64(head.ContentLength),
+			modTime: aws.ToTime(head.LastModified),
+		}, nil
+	}
+
+	if b.Exists(key) {
+		return s3FileInfo{name: path.Base(key), isDir: true}, nil
+	}
+
+	return nil, os.ErrNotExist
+}
+
+func (b *s3FS) List(key string) ([]os.FileInfo, error) {
+	ctx := context.Background()
+	objKey := b.objectKey(key)
+	if objKey != "" && !strings.HasSuffix(objKey, "/") {
+		objKey += "/"
+	}
+
+	out, err := b.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(b.bucket),
+		Prefix:    aws.String(objKey),
+		Delimiter: aws.String("/"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []os.FileInfo
+	for _, prefix := range out.CommonPrefixes {
+		name := strings.TrimSuffix(strings.TrimPrefix(aws.ToString(prefix.Prefix), objKey), "/")
+		infos = append(infos, s3FileInfo{name: name, isDir: true})
+	}
+	for _, obj := range out.Contents {
+		name := strings.TrimPrefix(aws.ToString(obj.Key), objKey)
+		if name == "" {
+			continue
+		}
+		infos = append(infos, s3FileInfo{
+			name:    name,
+			size:    aws.ToInt64(obj.Size),
+			modTime: aws.ToTime(obj.LastModified),
+		})
+	}
+	return infos, nil
+}
+
+func (b *s3FS) Open(key string) (io.ReadCloser, error) {
+	ctx := context.Background()
+
+	out, err := b.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.objectKey(key)),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, os.ErrNotExist
+		}
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+func (b *s3FS) Put(key string, r io.Reader) (int64, error) {
+	ctx := context.Background()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = b.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.objectKey(key)),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(data)), nil
+}
+
+func (b *s3FS) Delete(key string, recursive bool) error {
+	ctx := context.Background()
+	objKey := b.objectKey(key)
+
+	if !recursive {
+		_, err := b.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(b.bucket),
+			Key:    aws.String(objKey),
+		})
+		return err
+	}
+
+	prefix := objKey
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	var continuationToken *string
+	for {
+		out, err := b.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket:            aws.String(b.bucket),
+			Prefix:            aws.String(prefix),
+			ContinuationToken: continuationToken,
+		})
+		if err != nil {
+			return err
+		}
+
+		objects := make([]types.ObjectIdentifier, 0, len(out.Contents))
+		for _, obj := range out.Contents {
+			objects = append(objects, types.ObjectIdentifier{Key: obj.Key})
+		}
+		if len(objects) > 0 {
+			if _, err := b.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+				Bucket: aws.String(b.bucket),
+				Delete: &types.Delete{Objects: objects},
+			}); err != nil {
+				return err
+			}
+		}
+
+		if !aws.ToBool(out.IsTruncated) {
+			break
+		}
+		continuationToken = out.NextContinuationToken
+	}
+
+	_, err := b.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(objKey),
+	})
+	return err
+}
+
+// ServeFile copies the whole object body to w. It does not handle range
+// or conditional requests and does not set Content-Type.
+func (b *s3FS) ServeFile(w http.ResponseWriter, r *http.Request, key string) error {
+	body, err := b.Open(key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	_, err = io.Copy(w, body)
+	return err
+}
+
+func (b *s3FS) Exists(key string) bool {
+	ctx := context.Background()
+	objKey := b.objectKey(key)
+
+	if _, err := b.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(objKey),
+	}); err == nil {
+		return true
+	}
+
+	prefix := objKey
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	out, err := b.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+		Bucket:  aws.String(b.bucket),
+		Prefix:  aws.String(prefix),
+		MaxKeys: aws.Int32(1),
+	})
+	return err == nil && len(out.Contents) > 0
+}
+
+// WriteAt is not supported: plain S3 objects cannot be patched at an
+// arbitrary offset without a full rewrite, so range/resumable uploads
+// are not available on this backend.
+func (b *s3FS) WriteAt(key string, r io.Reader, offset int64) (int64, error) {
+	return 0, errors.New("range writes are not supported by the s3 backend")
+}
+
+// Mkdir creates a zero-byte marker object so the directory shows up in
+// listings even before it holds any files. S3 has no real directories.
+func (b *s3FS) Mkdir(key string) error {
+	ctx := context.Background()
+	objKey := b.objectKey(key)
+	if !strings.HasSuffix(objKey, "/") {
+		objKey += "/"
+	}
+
+	_, err := b.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(objKey),
+	})
+	return err
+}
+
+func (b *s3FS) Rename(src, dst string) error {
+	if err := b.Copy(src, dst); err != nil {
+		return err
+	}
+	return b.Delete(src, true)
+}
+
+func (b *s3FS) Copy(src, dst string) error {
+	ctx := context.Background()
+	srcKey, dstKey := b.objectKey(src), b.objectKey(dst)
+
+	if _, err := b.client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(b.bucket), Key: aws.String(srcKey)}); err == nil {
+		_, err := b.client.CopyObject(ctx, &s3.CopyObjectInput{
+			Bucket:     aws.String(b.bucket),
+			Key:        aws.String(dstKey),
+			CopySource: aws.String(b.bucket + "/" + srcKey),
+		})
+		return err
+	}
+
+	prefix := srcKey
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	var continuationToken *string
+	for {
+		out, err := b.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket:            aws.String(b.bucket),
+			Prefix:            aws.String(prefix),
+			ContinuationToken: continuationToken,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, obj := range out.Contents {
+			targetKey := dstKey + "/" + strings.TrimPrefix(aws.ToString(obj.Key), prefix)
+			if _, err := b.client.CopyObject(ctx, &s3.CopyObjectInput{
+				Bucket:     aws.String(b.bucket),
+				Key:        aws.String(targetKey),
+				CopySource: aws.String(b.bucket + "/" + aws.ToString(obj.Key)),
+			}); err != nil {
+				return err
+			}
+		}
+
+		if !aws.ToBool(out.IsTruncated) {
+			return nil
+		}
+		continuationToken = out.NextContinuationToken
+	}
+}